@@ -6,26 +6,46 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"bazel-metrics/analyzer/pkg/benchmark"
+	"bazel-metrics/analyzer/pkg/history"
 	"bazel-metrics/analyzer/pkg/metrics"
 	"bazel-metrics/analyzer/pkg/scanner"
 )
 
 func main() {
 	var (
-		repoPath      string
-		outputPath    string
-		runBenchmarks bool
-		maxBenchmarks int
-		prettyPrint   bool
+		repoPath         string
+		outputPath       string
+		runBenchmarks    bool
+		maxBenchmarks    int
+		benchmarkRuns    int
+		benchmarkTimeout time.Duration
+		prettyPrint      bool
+		scannerKind      string
+		scanConcurrency  int
+		scanTimeout      time.Duration
+		saveHistory      bool
+		comparePath      string
+		baselineBranch   string
+		regressionPct    float64
 	)
 
 	flag.StringVar(&repoPath, "repo", ".", "Path to the repository to analyze")
 	flag.StringVar(&outputPath, "output", "metrics.json", "Output file path for metrics JSON")
+	flag.StringVar(&scannerKind, "scanner", "regex", "Scanner backend to use: \"regex\" (grep BUILD files) or \"query\" (bazel query)")
+	flag.IntVar(&scanConcurrency, "scan-concurrency", 0, "Number of workers for parsing BUILD files (0 = runtime.NumCPU())")
+	flag.DurationVar(&scanTimeout, "scan-timeout", 0, "Maximum time to spend scanning the repository (0 = no limit)")
 	flag.BoolVar(&runBenchmarks, "benchmark", false, "Run speed benchmarks (go test vs bazel test)")
 	flag.IntVar(&maxBenchmarks, "max-benchmarks", 5, "Maximum number of packages to benchmark")
+	flag.IntVar(&benchmarkRuns, "runs", 6, "Number of timed runs per configuration (go test, bazel cold, bazel warm), excluding the discarded warm-up run")
+	flag.DurationVar(&benchmarkTimeout, "benchmark-timeout", 0, "Maximum total wall-clock time to spend benchmarking (0 = no limit)")
 	flag.BoolVar(&prettyPrint, "pretty", true, "Pretty print JSON output")
+	flag.BoolVar(&saveHistory, "save-history", true, "Persist this run under .bazel-metrics/history for future -compare runs")
+	flag.StringVar(&comparePath, "compare", "", "Path to a previous metrics.json to diff this run against")
+	flag.StringVar(&baselineBranch, "baseline-branch", "", "Git ref to scan in a temporary worktree and diff this run against, e.g. origin/main")
+	flag.Float64Var(&regressionPct, "regression-threshold", 10, "Minimum ns/op increase (percent) before a benchmark is flagged as a regression in trend reports")
 	flag.Parse()
 
 	// Resolve absolute path
@@ -45,7 +65,16 @@ func main() {
 
 	// Scan repository
 	fmt.Println("Scanning for Go packages and BUILD files...")
-	s := scanner.NewScanner(absRepoPath)
+	var s scanner.ScanRunner
+	switch scannerKind {
+	case "query":
+		s = scanner.NewQueryScanner(absRepoPath)
+	case "regex", "":
+		s = scanner.NewScanner(absRepoPath).WithConcurrency(scanConcurrency).WithTimeout(scanTimeout)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown -scanner value %q, using regex\n", scannerKind)
+		s = scanner.NewScanner(absRepoPath).WithConcurrency(scanConcurrency).WithTimeout(scanTimeout)
+	}
 	scanResult, err := s.Scan()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Scan error: %v\n", err)
@@ -73,6 +102,15 @@ func main() {
 	fmt.Printf("Bazelized Tests: %.1f%% (packages with tests that have go_test targets)\n",
 		report.Summary.BazelizedTestsPct)
 	fmt.Printf("Total go_test targets: %d\n", report.Summary.TotalGoTestTargets)
+	if len(report.Summary.TestsBySize) > 0 {
+		fmt.Printf("Packages with -race enabled: %d\n", report.Summary.PackagesWithRaceEnabled)
+		fmt.Println("go_test targets by size:")
+		for _, size := range []string{"small", "medium", "large", "enormous"} {
+			if n, ok := report.Summary.TestsBySize[size]; ok {
+				fmt.Printf("  %-10s %d\n", size, n)
+			}
+		}
+	}
 
 	fmt.Println("\n=== Top Directories ===")
 	for i, dir := range report.DirectoryBreakdown {
@@ -88,7 +126,7 @@ func main() {
 		fmt.Println("\n=== Running Speed Benchmarks ===")
 		fmt.Printf("This may take several minutes...\n")
 
-		runner := benchmark.NewRunner(absRepoPath, scanResult, maxBenchmarks)
+		runner := benchmark.NewRunner(absRepoPath, scanResult, maxBenchmarks, benchmarkRuns, benchmarkTimeout)
 		speedReport, err := runner.Run()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Benchmark error: %v\n", err)
@@ -98,10 +136,57 @@ func main() {
 			fmt.Println("\nBenchmark Results:")
 			for _, pkg := range speedReport.Packages {
 				fmt.Printf("  %s:\n", pkg.Path)
-				fmt.Printf("    go test:          %dms\n", pkg.GoTestMs)
-				fmt.Printf("    bazel test (cold): %dms\n", pkg.BazelTestColdMs)
-				fmt.Printf("    bazel test (warm): %dms\n", pkg.BazelTestWarmMs)
+				fmt.Printf("    go test:          mean %.1fms, median %.1fms, stddev %.1fms (cv %.2f)\n",
+					pkg.GoTestStats.Mean, pkg.GoTestStats.Median, pkg.GoTestStats.StdDev, pkg.GoTestStats.CV)
+				fmt.Printf("    bazel test (cold): mean %.1fms, median %.1fms, stddev %.1fms (cv %.2f)\n",
+					pkg.BazelColdStats.Mean, pkg.BazelColdStats.Median, pkg.BazelColdStats.StdDev, pkg.BazelColdStats.CV)
+				fmt.Printf("    bazel test (warm): mean %.1fms, median %.1fms, stddev %.1fms (cv %.2f)\n",
+					pkg.BazelWarmStats.Mean, pkg.BazelWarmStats.Median, pkg.BazelWarmStats.StdDev, pkg.BazelWarmStats.CV)
+				sig := ""
+				if pkg.Significant {
+					sig = " (significant)"
+				}
+				fmt.Printf("    delta: %.1f%%, p=%.4f%s\n", pkg.DeltaPct, pkg.PValue, sig)
+				if pkg.BazelWarmBreakdown != nil {
+					fmt.Printf("    bazel breakdown (warm): analysis %dms, execution %dms, critical path %dms, cache hit %.0f%%\n",
+						pkg.BazelWarmBreakdown.AnalysisMs, pkg.BazelWarmBreakdown.ExecutionMs,
+						pkg.BazelWarmBreakdown.CriticalPathMs, pkg.BazelWarmBreakdown.CacheHitRatio*100)
+				}
 			}
+			fmt.Printf("\nGeomean speedup (go test / bazel warm): %.2fx across %d package(s)\n",
+				speedReport.Summary.GeomeanSpeedup, speedReport.Summary.PackagesCompared)
+		}
+	}
+
+	// Compare against a prior run and/or a baseline branch, if requested.
+	var trend *history.TrendReport
+	if comparePath != "" {
+		previous, err := history.NewFileStore(absRepoPath).Load(comparePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Compare error: %v\n", err)
+		} else {
+			trend = history.Compare(previous, report, regressionPct)
+		}
+	}
+	if baselineBranch != "" {
+		baselineTrend, err := compareAgainstBaseline(absRepoPath, baselineBranch, scannerKind, regressionPct, report)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Baseline comparison error: %v\n", err)
+		} else {
+			trend = baselineTrend
+		}
+	}
+	if trend != nil {
+		printTrend(trend)
+	}
+
+	// Persist this run so future invocations can -compare against it.
+	if saveHistory {
+		path, err := history.NewFileStore(absRepoPath).Save(report)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to save history: %v\n", err)
+		} else {
+			fmt.Printf("Saved history to %s\n", path)
 		}
 	}
 
@@ -126,3 +211,52 @@ func main() {
 
 	fmt.Println("Done!")
 }
+
+// compareAgainstBaseline checks out ref into a temporary git worktree, scans
+// and calculates metrics for it the same way as the main run, and diffs
+// current against it.
+func compareAgainstBaseline(repoPath, ref, scannerKind string, regressionPct float64, current *metrics.Report) (*history.TrendReport, error) {
+	worktreePath, cleanup, err := history.CreateBaselineWorktree(repoPath, ref)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	var baselineScanner scanner.ScanRunner
+	if scannerKind == "query" {
+		baselineScanner = scanner.NewQueryScanner(worktreePath)
+	} else {
+		baselineScanner = scanner.NewScanner(worktreePath)
+	}
+
+	baselineScan, err := baselineScanner.Scan()
+	if err != nil {
+		return nil, fmt.Errorf("scan baseline %s: %w", ref, err)
+	}
+
+	baselineReport := metrics.NewCalculator(baselineScan).Calculate()
+	return history.Compare(baselineReport, current, regressionPct), nil
+}
+
+// printTrend prints a TrendReport's headline numbers to stdout.
+func printTrend(trend *history.TrendReport) {
+	fmt.Println("\n=== Trend vs previous run ===")
+	fmt.Printf("Bazelization:  %+.1f%%\n", trend.BazelizationPctDelta)
+	fmt.Printf("Test coverage: %+.1f%%\n", trend.TestCoveragePctDelta)
+	if len(trend.NewlyBazelized) > 0 {
+		fmt.Printf("Newly bazelized:   %d package(s)\n", len(trend.NewlyBazelized))
+	}
+	if len(trend.NewlyUnbazelized) > 0 {
+		fmt.Printf("Newly un-bazelized: %d package(s)\n", len(trend.NewlyUnbazelized))
+	}
+	if len(trend.GainedGoTestTargets) > 0 {
+		fmt.Printf("Gained go_test targets: %d package(s)\n", len(trend.GainedGoTestTargets))
+	}
+	if len(trend.LostGoTestTargets) > 0 {
+		fmt.Printf("Lost go_test targets:   %d package(s)\n", len(trend.LostGoTestTargets))
+	}
+	for _, reg := range trend.BenchmarkRegressions {
+		fmt.Printf("  REGRESSION %s.%s: %.0fns/op -> %.0fns/op (%+.1f%%)\n",
+			reg.Package, reg.Benchmark, reg.PreviousNsPerOp, reg.CurrentNsPerOp, reg.DeltaPct)
+	}
+}
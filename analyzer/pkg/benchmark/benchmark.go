@@ -12,22 +12,34 @@ import (
 	"bazel-metrics/analyzer/pkg/scanner"
 )
 
+const defaultRuns = 6
+
 // Runner executes benchmarks comparing go test vs bazel test
 type Runner struct {
 	repoPath   string
 	scanResult *scanner.ScanResult
 	maxTests   int
+	runs       int
+	timeout    time.Duration
 }
 
-// NewRunner creates a new benchmark runner
-func NewRunner(repoPath string, result *scanner.ScanResult, maxTests int) *Runner {
+// NewRunner creates a new benchmark runner. runs is the number of times each
+// configuration (go test, bazel cold, bazel warm) is executed per package;
+// the first run of each is treated as a warm-up and discarded from the
+// statistics. timeout bounds the total wall-clock budget for the whole run.
+func NewRunner(repoPath string, result *scanner.ScanResult, maxTests, runs int, timeout time.Duration) *Runner {
 	if maxTests <= 0 {
 		maxTests = 5
 	}
+	if runs <= 0 {
+		runs = defaultRuns
+	}
 	return &Runner{
 		repoPath:   repoPath,
 		scanResult: result,
 		maxTests:   maxTests,
+		runs:       runs,
+		timeout:    timeout,
 	}
 }
 
@@ -48,8 +60,18 @@ func (r *Runner) Run() (*metrics.SpeedReport, error) {
 		candidates = candidates[:r.maxTests]
 	}
 
+	deadline := time.Time{}
+	if r.timeout > 0 {
+		deadline = time.Now().Add(r.timeout)
+	}
+
 	for _, pkg := range candidates {
-		benchmark, err := r.benchmarkPackage(pkg)
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			fmt.Fprintf(os.Stderr, "Warning: benchmark timeout reached, skipping remaining packages\n")
+			break
+		}
+
+		benchmark, err := r.benchmarkPackage(pkg, deadline)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to benchmark %s: %v\n", pkg.RelPath, err)
 			continue
@@ -57,9 +79,24 @@ func (r *Runner) Run() (*metrics.SpeedReport, error) {
 		report.Packages = append(report.Packages, *benchmark)
 	}
 
+	report.Summary = summarize(report.Packages)
+
 	return report, nil
 }
 
+func summarize(packages []metrics.PackageBenchmark) metrics.SpeedSummary {
+	var ratios []float64
+	for _, pkg := range packages {
+		if pkg.GoTestStats.Mean > 0 && pkg.BazelWarmStats.Mean > 0 {
+			ratios = append(ratios, pkg.GoTestStats.Mean/pkg.BazelWarmStats.Mean)
+		}
+	}
+	return metrics.SpeedSummary{
+		PackagesCompared: len(ratios),
+		GeomeanSpeedup:   metrics.Geomean(ratios),
+	}
+}
+
 func (r *Runner) selectCandidates() []*scanner.Package {
 	var candidates []*scanner.Package
 
@@ -85,39 +122,183 @@ func (r *Runner) selectCandidates() []*scanner.Package {
 	return candidates
 }
 
-func (r *Runner) benchmarkPackage(pkg *scanner.Package) (*metrics.PackageBenchmark, error) {
+func (r *Runner) benchmarkPackage(pkg *scanner.Package, deadline time.Time) (*metrics.PackageBenchmark, error) {
 	benchmark := &metrics.PackageBenchmark{
 		Path: pkg.RelPath,
 	}
 
-	// Benchmark go test
-	goTestTime, err := r.runGoTest(pkg)
+	goSamples, err := r.runNTimes(r.runs, deadline, func() (int64, error) {
+		return r.runGoTest(pkg)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("go test failed: %w", err)
 	}
-	benchmark.GoTestMs = goTestTime
+	benchmark.GoTestSamples = goSamples
+	benchmark.GoTestStats = metrics.ComputeStats(goSamples)
 
-	// Clean bazel cache for cold run
+	// Cold breakdown: clean the cache immediately before the one BEP capture
+	// run so it reflects a genuinely cold build.
 	r.cleanBazelCache()
+	_, coldBreakdown, err := r.runBazelTestWithBEP(pkg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: bazel BEP capture (cold) had issues for %s: %v\n", pkg.RelPath, err)
+	}
+	benchmark.BazelColdBreakdown = coldBreakdown
 
-	// Benchmark bazel test (cold)
-	bazelColdTime, err := r.runBazelTest(pkg)
+	// Cold samples: clean the cache before every iteration, not just once,
+	// otherwise runNTimes's warm-up discard throws away the only cold run
+	// and BazelColdStats ends up measuring the same warm cache as
+	// BazelWarmStats below.
+	coldSamples, err := r.runNTimesCold(r.runs, deadline, func() (int64, error) {
+		return r.runBazelTest(pkg)
+	})
 	if err != nil {
-		// Bazel test may fail, but we still want timing
-		fmt.Fprintf(os.Stderr, "Warning: bazel test had issues for %s: %v\n", pkg.RelPath, err)
+		fmt.Fprintf(os.Stderr, "Warning: bazel test (cold) had issues for %s: %v\n", pkg.RelPath, err)
 	}
-	benchmark.BazelTestColdMs = bazelColdTime
+	benchmark.BazelColdSamples = coldSamples
+	benchmark.BazelColdStats = metrics.ComputeStats(coldSamples)
 
-	// Benchmark bazel test (warm - second run)
-	bazelWarmTime, err := r.runBazelTest(pkg)
+	// Warm runs reuse whatever bazel cached from the cold runs above.
+	warmSamples, err := r.runNTimes(r.runs, deadline, func() (int64, error) {
+		return r.runBazelTest(pkg)
+	})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: bazel test warm run had issues for %s: %v\n", pkg.RelPath, err)
+		fmt.Fprintf(os.Stderr, "Warning: bazel test (warm) had issues for %s: %v\n", pkg.RelPath, err)
 	}
-	benchmark.BazelTestWarmMs = bazelWarmTime
+	benchmark.BazelWarmSamples = warmSamples
+	benchmark.BazelWarmStats = metrics.ComputeStats(warmSamples)
+
+	_, warmBreakdown, err := r.runBazelTestWithBEP(pkg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: bazel BEP capture (warm) had issues for %s: %v\n", pkg.RelPath, err)
+	}
+	benchmark.BazelWarmBreakdown = warmBreakdown
+
+	if benchmark.GoTestStats.Mean > 0 {
+		benchmark.DeltaPct = (benchmark.BazelWarmStats.Mean - benchmark.GoTestStats.Mean) / benchmark.GoTestStats.Mean * 100
+	}
+	benchmark.PValue = metrics.WelchTTest(benchmark.GoTestSamples, benchmark.BazelWarmSamples)
+	benchmark.Significant = benchmark.PValue < 0.05
+
+	goBenches, err := r.runGoBench(pkg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: go bench failed for %s: %v\n", pkg.RelPath, err)
+	}
+	benchmark.GoBenchmarks = goBenches
+
+	bazelBenches, err := r.runBazelBench(pkg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: bazel bench failed for %s: %v\n", pkg.RelPath, err)
+	}
+	benchmark.BazelBenchmarks = bazelBenches
+
+	benchmark.BenchmarkDeltas = metrics.CompareBenchmarks(goBenches, bazelBenches)
 
 	return benchmark, nil
 }
 
+// runGoBench runs `go test -bench=. -benchmem -count=N` for pkg and parses
+// the resulting benchmark lines.
+func (r *Runner) runGoBench(pkg *scanner.Package) ([]metrics.Benchmark, error) {
+	pkgDir := pkg.Path
+	importPath := "./" + pkg.RelPath
+	if strings.HasPrefix(pkg.RelPath, "go/") {
+		importPath = "./" + strings.TrimPrefix(pkg.RelPath, "go/")
+		pkgDir = filepath.Join(r.repoPath, "go")
+	}
+
+	cmd := exec.Command("go", "test", "-run=^$", "-bench=.", "-benchmem",
+		fmt.Sprintf("-count=%d", r.runs), importPath)
+	cmd.Dir = pkgDir
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0")
+
+	out, err := cmd.CombinedOutput()
+	benches := parseBenchmarkOutput(string(out))
+	if err != nil && len(benches) == 0 {
+		return nil, fmt.Errorf("go test -bench: %w", err)
+	}
+	return benches, nil
+}
+
+// runBazelBench runs the equivalent benchmark under `bazel test` by passing
+// the benchmark flags through as test arguments.
+func (r *Runner) runBazelBench(pkg *scanner.Package) ([]metrics.Benchmark, error) {
+	target := "//" + pkg.RelPath + ":all"
+
+	cmd := exec.Command("bazel", "test", target,
+		"--test_output=all",
+		"--test_arg=-test.run=^$",
+		"--test_arg=-test.bench=.",
+		"--test_arg=-test.benchmem",
+		fmt.Sprintf("--test_arg=-test.count=%d", r.runs),
+	)
+	cmd.Dir = r.repoPath
+
+	out, err := cmd.CombinedOutput()
+	benches := parseBenchmarkOutput(string(out))
+	if err != nil && len(benches) == 0 {
+		return nil, fmt.Errorf("bazel test -test.bench: %w", err)
+	}
+	return benches, nil
+}
+
+// runNTimes runs fn r.runs+1 times (one extra warm-up run) and returns the
+// samples with the warm-up discarded. It stops early, returning whatever
+// samples it already collected, if the deadline passes.
+func (r *Runner) runNTimes(n int, deadline time.Time, fn func() (int64, error)) ([]int64, error) {
+	total := n + 1
+	samples := make([]int64, 0, n)
+	var lastErr error
+
+	for i := 0; i < total; i++ {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			break
+		}
+
+		ms, err := fn()
+		if err != nil {
+			lastErr = err
+		}
+		if i == 0 {
+			// Discard the warm-up measurement.
+			continue
+		}
+		samples = append(samples, ms)
+	}
+
+	if len(samples) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return samples, nil
+}
+
+// runNTimesCold is like runNTimes but cleans the bazel cache before every
+// iteration instead of once up front. Each run is therefore independently
+// cold, so there's nothing to treat as a warm-up to discard.
+func (r *Runner) runNTimesCold(n int, deadline time.Time, fn func() (int64, error)) ([]int64, error) {
+	samples := make([]int64, 0, n)
+	var lastErr error
+
+	for i := 0; i < n; i++ {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			break
+		}
+
+		r.cleanBazelCache()
+		ms, err := fn()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		samples = append(samples, ms)
+	}
+
+	if len(samples) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return samples, nil
+}
+
 func (r *Runner) runGoTest(pkg *scanner.Package) (int64, error) {
 	pkgDir := pkg.Path
 
@@ -0,0 +1,86 @@
+package benchmark
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+
+	"bazel-metrics/analyzer/pkg/metrics"
+)
+
+// parseBenchmarkOutput parses the standard `go test -bench` text format,
+// e.g.:
+//
+//	BenchmarkFib-8   	  500000	      2130 ns/op	      16 B/op	       1 allocs/op	     800.00 MB/s
+//
+// It tolerates missing optional columns (B/op, allocs/op, MB/s) and ignores
+// lines that aren't benchmark results, such as "PASS" or "ok   pkg  0.123s".
+func parseBenchmarkOutput(output string) []metrics.Benchmark {
+	var benchmarks []metrics.Benchmark
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if b, ok := parseBenchmarkLine(line); ok {
+			benchmarks = append(benchmarks, b)
+		}
+	}
+
+	return benchmarks
+}
+
+func parseBenchmarkLine(line string) (metrics.Benchmark, bool) {
+	if !strings.HasPrefix(line, "Benchmark") {
+		return metrics.Benchmark{}, false
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return metrics.Benchmark{}, false
+	}
+
+	nameAndProcs := fields[0]
+	name := nameAndProcs
+	procs := 0
+	if idx := strings.LastIndex(nameAndProcs, "-"); idx != -1 {
+		if p, err := strconv.Atoi(nameAndProcs[idx+1:]); err == nil {
+			name = nameAndProcs[:idx]
+			procs = p
+		}
+	}
+
+	n, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return metrics.Benchmark{}, false
+	}
+
+	b := metrics.Benchmark{Name: name, Procs: procs, N: n}
+
+	// Remaining fields come in (value, unit) pairs; walk them rather than
+	// assuming a fixed layout so missing optional columns don't shift things.
+	for i := 2; i+1 < len(fields); i += 2 {
+		value := fields[i]
+		unit := fields[i+1]
+
+		switch unit {
+		case "ns/op":
+			if v, err := strconv.ParseFloat(value, 64); err == nil {
+				b.NsPerOp = v
+			}
+		case "B/op":
+			if v, err := strconv.ParseUint(value, 10, 64); err == nil {
+				b.AllocedBytesPerOp = v
+			}
+		case "allocs/op":
+			if v, err := strconv.ParseUint(value, 10, 64); err == nil {
+				b.AllocsPerOp = v
+			}
+		case "MB/s":
+			if v, err := strconv.ParseFloat(value, 64); err == nil {
+				b.MBPerS = v
+			}
+		}
+	}
+
+	return b, true
+}
@@ -0,0 +1,116 @@
+package benchmark
+
+import (
+	"testing"
+)
+
+func TestParseBenchmarkLine(t *testing.T) {
+	tests := []struct {
+		name        string
+		line        string
+		wantOK      bool
+		wantBench   string
+		wantProcs   int
+		wantN       int
+		wantNsPerOp float64
+		wantAllocB  uint64
+		wantAllocs  uint64
+		wantMBPerS  float64
+	}{
+		{
+			name:        "full line",
+			line:        "BenchmarkFib-8   \t  500000\t      2130 ns/op\t      16 B/op\t       1 allocs/op\t     800.00 MB/s",
+			wantOK:      true,
+			wantBench:   "BenchmarkFib",
+			wantProcs:   8,
+			wantN:       500000,
+			wantNsPerOp: 2130,
+			wantAllocB:  16,
+			wantAllocs:  1,
+			wantMBPerS:  800,
+		},
+		{
+			name:        "missing optional columns",
+			line:        "BenchmarkSum-4   1000000   103 ns/op",
+			wantOK:      true,
+			wantBench:   "BenchmarkSum",
+			wantProcs:   4,
+			wantN:       1000000,
+			wantNsPerOp: 103,
+		},
+		{
+			name:        "no proc suffix",
+			line:        "BenchmarkNoProcs   1000   50 ns/op",
+			wantOK:      true,
+			wantBench:   "BenchmarkNoProcs",
+			wantProcs:   0,
+			wantN:       1000,
+			wantNsPerOp: 50,
+		},
+		{
+			name:   "not a benchmark line",
+			line:   "PASS",
+			wantOK: false,
+		},
+		{
+			name:   "ok summary line",
+			line:   "ok  	bazel-metrics/analyzer/pkg/benchmark	0.123s",
+			wantOK: false,
+		},
+		{
+			name:   "too few fields",
+			line:   "BenchmarkTooShort-4 100",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, ok := parseBenchmarkLine(tt.line)
+			if ok != tt.wantOK {
+				t.Fatalf("parseBenchmarkLine(%q) ok = %v, want %v", tt.line, ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if b.Name != tt.wantBench {
+				t.Errorf("Name = %q, want %q", b.Name, tt.wantBench)
+			}
+			if b.Procs != tt.wantProcs {
+				t.Errorf("Procs = %d, want %d", b.Procs, tt.wantProcs)
+			}
+			if b.N != tt.wantN {
+				t.Errorf("N = %d, want %d", b.N, tt.wantN)
+			}
+			if b.NsPerOp != tt.wantNsPerOp {
+				t.Errorf("NsPerOp = %v, want %v", b.NsPerOp, tt.wantNsPerOp)
+			}
+			if b.AllocedBytesPerOp != tt.wantAllocB {
+				t.Errorf("AllocedBytesPerOp = %d, want %d", b.AllocedBytesPerOp, tt.wantAllocB)
+			}
+			if b.AllocsPerOp != tt.wantAllocs {
+				t.Errorf("AllocsPerOp = %d, want %d", b.AllocsPerOp, tt.wantAllocs)
+			}
+			if b.MBPerS != tt.wantMBPerS {
+				t.Errorf("MBPerS = %v, want %v", b.MBPerS, tt.wantMBPerS)
+			}
+		})
+	}
+}
+
+func TestParseBenchmarkOutput(t *testing.T) {
+	output := `goos: linux
+goarch: amd64
+BenchmarkFib-8   	  500000	      2130 ns/op
+BenchmarkSum-8   	 1000000	       103 ns/op
+PASS
+ok  	example/pkg	2.531s
+`
+	benches := parseBenchmarkOutput(output)
+	if len(benches) != 2 {
+		t.Fatalf("parseBenchmarkOutput() returned %d benchmarks, want 2", len(benches))
+	}
+	if benches[0].Name != "BenchmarkFib" || benches[1].Name != "BenchmarkSum" {
+		t.Errorf("unexpected benchmark names: %+v", benches)
+	}
+}
@@ -0,0 +1,201 @@
+package benchmark
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"bazel-metrics/analyzer/pkg/metrics"
+	"bazel-metrics/analyzer/pkg/scanner"
+)
+
+// runBazelTestWithBEP runs `bazel test` for pkg with Build Event Protocol
+// output enabled and returns a phase-accurate breakdown parsed from it,
+// instead of a single time.Since(start) that conflates startup, analysis,
+// action execution, and test runtime.
+func (r *Runner) runBazelTestWithBEP(pkg *scanner.Package) (int64, *metrics.BazelTestBreakdown, error) {
+	target := "//" + pkg.RelPath + ":all"
+
+	bepFile, err := os.CreateTemp("", "bazel-metrics-bep-*.json")
+	if err != nil {
+		return 0, nil, fmt.Errorf("create BEP temp file: %w", err)
+	}
+	bepPath := bepFile.Name()
+	bepFile.Close()
+	defer os.Remove(bepPath)
+
+	profilePath := bepPath + ".profile.gz"
+	defer os.Remove(profilePath)
+
+	cmd := exec.Command("bazel", "test", target,
+		"--test_output=errors",
+		"--build_event_json_file="+bepPath,
+		"--build_event_publish_all_actions",
+		"--profile="+profilePath,
+		"--noslim_profile",
+	)
+	cmd.Dir = r.repoPath
+
+	start := time.Now()
+	runErr := cmd.Run()
+	elapsed := time.Since(start).Milliseconds()
+
+	breakdown, parseErr := parseBEPFile(bepPath)
+	if parseErr != nil {
+		return elapsed, nil, fmt.Errorf("parse BEP file: %w", parseErr)
+	}
+	breakdown.TotalMs = elapsed
+
+	// BEP's buildMetrics.timingMetrics doesn't carry the critical path, so
+	// it's only available from the chrome-trace profile.
+	if criticalPathMs, err := criticalPathFromProfile(profilePath); err == nil {
+		breakdown.CriticalPathMs = criticalPathMs
+	}
+
+	return elapsed, breakdown, runErr
+}
+
+// criticalPathFromProfile reads Bazel's gzip'd chrome-trace profile and sums
+// the duration of the "Critical Path" trace events. BEP's buildMetrics
+// doesn't expose the critical path, so this is the only source for it.
+func criticalPathFromProfile(path string) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return 0, err
+	}
+	defer gz.Close()
+
+	var trace struct {
+		TraceEvents []struct {
+			Name       string  `json:"name"`
+			DurationUs float64 `json:"dur"`
+		} `json:"traceEvents"`
+	}
+	if err := json.NewDecoder(gz).Decode(&trace); err != nil {
+		return 0, err
+	}
+
+	var criticalPathUs float64
+	for _, ev := range trace.TraceEvents {
+		if ev.Name == "Critical Path" {
+			criticalPathUs += ev.DurationUs
+		}
+	}
+
+	return int64(criticalPathUs / 1000), nil
+}
+
+// parseBEPFile reads a Build Event Protocol JSON file (one JSON object per
+// line) and extracts timing and action-cache information. It's tolerant of
+// missing fields since the exact event set varies across Bazel versions.
+func parseBEPFile(path string) (*metrics.BazelTestBreakdown, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	breakdown := &metrics.BazelTestBreakdown{}
+	var totalActions int
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var event map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue // tolerate stray/partial lines
+		}
+
+		if metricsPayload, ok := mapAt(event, "buildMetrics"); ok {
+			if timing, ok := mapAt(metricsPayload, "timingMetrics"); ok {
+				breakdown.AnalysisMs += int64At(timing, "analysisPhaseTimeInMs")
+				breakdown.ExecutionMs += int64At(timing, "executionPhaseTimeInMs")
+			}
+		}
+
+		if result, ok := mapAt(event, "testResult"); ok {
+			breakdown.TestAttempts = append(breakdown.TestAttempts, TestAttempt(result))
+		}
+
+		// RemoteActions/LocalActions/CachedActions/totalActions all come
+		// from the same population (per-action "action" events), so
+		// CacheHitRatio below is a ratio over one consistent denominator
+		// rather than mixing build-action and test-attempt counts.
+		if action, ok := mapAt(event, "action"); ok {
+			if !boolAt(action, "success") {
+				continue
+			}
+			totalActions++
+			switch {
+			case boolAt(action, "cached"):
+				breakdown.CachedActions++
+			case strAt(action, "strategy") == "remote":
+				breakdown.RemoteActions++
+			default:
+				breakdown.LocalActions++
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if totalActions > 0 {
+		breakdown.CacheHitRatio = float64(breakdown.CachedActions) / float64(totalActions)
+	}
+
+	return breakdown, nil
+}
+
+// TestAttempt builds a metrics.TestAttempt out of a decoded BEP testResult
+// payload.
+func TestAttempt(result map[string]interface{}) metrics.TestAttempt {
+	return metrics.TestAttempt{
+		Attempt:    int(int64At(result, "attempt")),
+		DurationMs: int64At(result, "testAttemptDurationMillis"),
+		Status:     strAt(result, "status"),
+		CacheHit:   boolAt(result, "cachedLocally") || boolAt(result, "cacheHit"),
+	}
+}
+
+func mapAt(m map[string]interface{}, key string) (map[string]interface{}, bool) {
+	v, ok := m[key]
+	if !ok {
+		return nil, false
+	}
+	nested, ok := v.(map[string]interface{})
+	return nested, ok
+}
+
+func int64At(m map[string]interface{}, key string) int64 {
+	switch v := m[key].(type) {
+	case float64:
+		return int64(v)
+	case string:
+		var n int64
+		fmt.Sscanf(v, "%d", &n)
+		return n
+	default:
+		return 0
+	}
+}
+
+func boolAt(m map[string]interface{}, key string) bool {
+	b, _ := m[key].(bool)
+	return b
+}
+
+func strAt(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
@@ -0,0 +1,39 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// CreateBaselineWorktree checks out ref into a temporary git worktree under
+// repoPath, so it can be scanned and compared against the working tree
+// without disturbing it. Call the returned cleanup func (even on error
+// paths where a partial worktree may have been created) to remove it.
+func CreateBaselineWorktree(repoPath, ref string) (worktreePath string, cleanup func() error, err error) {
+	dir, err := os.MkdirTemp("", "bazel-metrics-baseline-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("create baseline temp dir: %w", err)
+	}
+
+	cleanup = func() error {
+		cmd := exec.Command("git", "worktree", "remove", "--force", dir)
+		cmd.Dir = repoPath
+		if err := cmd.Run(); err != nil {
+			// Worktree add may have failed before the worktree was
+			// registered with git; fall back to a plain directory removal.
+			return os.RemoveAll(dir)
+		}
+		return nil
+	}
+
+	cmd := exec.Command("git", "worktree", "add", "--detach", dir, ref)
+	cmd.Dir = repoPath
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		os.RemoveAll(dir)
+		return "", func() error { return nil }, fmt.Errorf("git worktree add %s: %w", ref, err)
+	}
+
+	return dir, cleanup, nil
+}
@@ -0,0 +1,120 @@
+// Package history persists metrics.Report runs and diffs them against each
+// other, so a CI check can answer "did this change move bazelization
+// forward?" rather than only showing a single point-in-time snapshot.
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"bazel-metrics/analyzer/pkg/metrics"
+)
+
+// Dir is the default directory (relative to the repo being analyzed) that
+// history is persisted under.
+const Dir = ".bazel-metrics/history"
+
+// Store persists and retrieves metrics.Report runs. The default
+// implementation is a directory of JSON files; build with the "sqlite" tag
+// to use a SQLite-backed Store instead.
+type Store interface {
+	// Save persists report and returns an identifier that can be passed to
+	// Load later (e.g. a file path).
+	Save(report *metrics.Report) (string, error)
+	// Load retrieves a previously saved report by the identifier Save
+	// returned, or by a path passed directly via -compare.
+	Load(id string) (*metrics.Report, error)
+}
+
+// FileStore is the default Store: one JSON file per run, named after its
+// report timestamp, under <repoPath>/.bazel-metrics/history/.
+type FileStore struct {
+	repoPath string
+}
+
+// NewFileStore creates a FileStore rooted at repoPath.
+func NewFileStore(repoPath string) *FileStore {
+	return &FileStore{repoPath: repoPath}
+}
+
+func (s *FileStore) dir() string {
+	return filepath.Join(s.repoPath, Dir)
+}
+
+// Save writes report to <dir>/<timestamp>.json, replacing ":" in the
+// timestamp so the filename is valid on all platforms.
+func (s *FileStore) Save(report *metrics.Report) (string, error) {
+	if err := os.MkdirAll(s.dir(), 0755); err != nil {
+		return "", fmt.Errorf("create history dir: %w", err)
+	}
+
+	name := sanitizeTimestamp(report.Timestamp) + ".json"
+	path := filepath.Join(s.dir(), name)
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("write report: %w", err)
+	}
+
+	return path, nil
+}
+
+// Load reads a report from the given path. If id isn't a path that exists
+// as-is, it's tried relative to the history directory.
+func (s *FileStore) Load(id string) (*metrics.Report, error) {
+	path := id
+	if _, err := os.Stat(path); err != nil {
+		path = filepath.Join(s.dir(), id)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read report %s: %w", id, err)
+	}
+
+	var report metrics.Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parse report %s: %w", id, err)
+	}
+	return &report, nil
+}
+
+// List returns every saved report's identifier, oldest first.
+func (s *FileStore) List() ([]string, error) {
+	entries, err := os.ReadDir(s.dir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var ids []string
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		ids = append(ids, filepath.Join(s.dir(), e.Name()))
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func sanitizeTimestamp(ts string) string {
+	out := make([]rune, 0, len(ts))
+	for _, r := range ts {
+		switch r {
+		case ':':
+			out = append(out, '-')
+		default:
+			out = append(out, r)
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,84 @@
+//go:build sqlite
+
+package history
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"bazel-metrics/analyzer/pkg/metrics"
+)
+
+// SQLiteStore is an alternative to FileStore for repos that want a single
+// queryable history file instead of one JSON file per run. Only built when
+// compiled with -tags sqlite, since it pulls in a cgo sqlite driver.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens (creating if necessary) a SQLite database at
+// <repoPath>/.bazel-metrics/history.db.
+func NewSQLiteStore(repoPath string) (*SQLiteStore, error) {
+	dir := filepath.Join(repoPath, Dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create history dir: %w", err)
+	}
+
+	path := filepath.Join(dir, "history.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite history db: %w", err)
+	}
+
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS reports (
+		timestamp TEXT PRIMARY KEY,
+		data      TEXT NOT NULL
+	)`); err != nil {
+		return nil, fmt.Errorf("create reports table: %w", err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+// Save implements Store.
+func (s *SQLiteStore) Save(report *metrics.Report) (string, error) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("marshal report: %w", err)
+	}
+
+	_, err = s.db.Exec(
+		`INSERT OR REPLACE INTO reports (timestamp, data) VALUES (?, ?)`,
+		report.Timestamp, string(data),
+	)
+	if err != nil {
+		return "", fmt.Errorf("insert report: %w", err)
+	}
+
+	return report.Timestamp, nil
+}
+
+// Load implements Store.
+func (s *SQLiteStore) Load(id string) (*metrics.Report, error) {
+	var data string
+	err := s.db.QueryRow(`SELECT data FROM reports WHERE timestamp = ?`, id).Scan(&data)
+	if err != nil {
+		return nil, fmt.Errorf("load report %s: %w", id, err)
+	}
+
+	var report metrics.Report
+	if err := json.Unmarshal([]byte(data), &report); err != nil {
+		return nil, fmt.Errorf("parse report %s: %w", id, err)
+	}
+	return &report, nil
+}
+
+// Close releases the underlying database handle.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
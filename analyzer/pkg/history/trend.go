@@ -0,0 +1,168 @@
+package history
+
+import (
+	"sort"
+
+	"bazel-metrics/analyzer/pkg/metrics"
+)
+
+// TrendReport diffs two metrics.Report runs, so a reviewer or CI check can
+// see whether a change moved bazelization forward or backward rather than
+// only seeing the current snapshot.
+type TrendReport struct {
+	PreviousTimestamp string `json:"previousTimestamp"`
+	CurrentTimestamp  string `json:"currentTimestamp"`
+
+	BazelizationPctDelta float64 `json:"bazelizationPctDelta"`
+	TestCoveragePctDelta float64 `json:"testCoveragePctDelta"`
+
+	DirectoryDeltas []DirectoryDelta `json:"directoryDeltas,omitempty"`
+
+	NewlyBazelized      []string `json:"newlyBazelized,omitempty"`
+	NewlyUnbazelized    []string `json:"newlyUnbazelized,omitempty"`
+	GainedGoTestTargets []string `json:"gainedGoTestTargets,omitempty"`
+	LostGoTestTargets   []string `json:"lostGoTestTargets,omitempty"`
+
+	BenchmarkRegressions []BenchmarkRegression `json:"benchmarkRegressions,omitempty"`
+}
+
+// DirectoryDelta is the per-top-level-directory change between two runs.
+type DirectoryDelta struct {
+	Name                 string  `json:"name"`
+	BazelizationPctDelta float64 `json:"bazelizationPctDelta"`
+	TestCoveragePctDelta float64 `json:"testCoveragePctDelta"`
+}
+
+// BenchmarkRegression flags a microbenchmark that got slower by more than
+// the caller's threshold between two runs.
+type BenchmarkRegression struct {
+	Package         string  `json:"package"`
+	Benchmark       string  `json:"benchmark"`
+	PreviousNsPerOp float64 `json:"previousNsPerOp"`
+	CurrentNsPerOp  float64 `json:"currentNsPerOp"`
+	DeltaPct        float64 `json:"deltaPct"`
+}
+
+// Compare builds a TrendReport from a previous and current run.
+// regressionThresholdPct is the minimum ns/op increase (e.g. 10 for 10%)
+// before a benchmark is flagged as a regression.
+func Compare(previous, current *metrics.Report, regressionThresholdPct float64) *TrendReport {
+	trend := &TrendReport{
+		PreviousTimestamp:    previous.Timestamp,
+		CurrentTimestamp:     current.Timestamp,
+		BazelizationPctDelta: current.Summary.BazelizationPct - previous.Summary.BazelizationPct,
+		TestCoveragePctDelta: current.Summary.TestCoveragePct - previous.Summary.TestCoveragePct,
+	}
+
+	trend.DirectoryDeltas = compareDirectories(previous.DirectoryBreakdown, current.DirectoryBreakdown)
+
+	prevPkgs := make(map[string]*metrics.PackageInfo, len(previous.Packages))
+	for _, p := range previous.Packages {
+		prevPkgs[p.Path] = p
+	}
+	currPkgs := make(map[string]*metrics.PackageInfo, len(current.Packages))
+	for _, p := range current.Packages {
+		currPkgs[p.Path] = p
+	}
+
+	for path, curr := range currPkgs {
+		prev, existed := prevPkgs[path]
+		if !existed {
+			continue
+		}
+		if !prev.HasBuildFile && curr.HasBuildFile {
+			trend.NewlyBazelized = append(trend.NewlyBazelized, path)
+		}
+		if prev.HasBuildFile && !curr.HasBuildFile {
+			trend.NewlyUnbazelized = append(trend.NewlyUnbazelized, path)
+		}
+		if prev.GoTestTargetCount == 0 && curr.GoTestTargetCount > 0 {
+			trend.GainedGoTestTargets = append(trend.GainedGoTestTargets, path)
+		}
+		if prev.GoTestTargetCount > 0 && curr.GoTestTargetCount == 0 {
+			trend.LostGoTestTargets = append(trend.LostGoTestTargets, path)
+		}
+	}
+	sort.Strings(trend.NewlyBazelized)
+	sort.Strings(trend.NewlyUnbazelized)
+	sort.Strings(trend.GainedGoTestTargets)
+	sort.Strings(trend.LostGoTestTargets)
+
+	if previous.SpeedComparison != nil && current.SpeedComparison != nil {
+		trend.BenchmarkRegressions = compareBenchmarks(
+			previous.SpeedComparison.Packages, current.SpeedComparison.Packages, regressionThresholdPct)
+	}
+
+	return trend
+}
+
+func compareDirectories(previous, current []*metrics.DirectoryMetrics) []DirectoryDelta {
+	prevByName := make(map[string]*metrics.DirectoryMetrics, len(previous))
+	for _, d := range previous {
+		prevByName[d.Name] = d
+	}
+
+	var deltas []DirectoryDelta
+	for _, curr := range current {
+		prev, ok := prevByName[curr.Name]
+		if !ok {
+			deltas = append(deltas, DirectoryDelta{
+				Name:                 curr.Name,
+				BazelizationPctDelta: curr.BazelizationPct,
+				TestCoveragePctDelta: curr.TestCoveragePct,
+			})
+			continue
+		}
+		deltas = append(deltas, DirectoryDelta{
+			Name:                 curr.Name,
+			BazelizationPctDelta: curr.BazelizationPct - prev.BazelizationPct,
+			TestCoveragePctDelta: curr.TestCoveragePct - prev.TestCoveragePct,
+		})
+	}
+
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i].Name < deltas[j].Name })
+	return deltas
+}
+
+func compareBenchmarks(previous, current []metrics.PackageBenchmark, thresholdPct float64) []BenchmarkRegression {
+	prevBenches := make(map[string]map[string]metrics.Benchmark)
+	for _, pkg := range previous {
+		m := make(map[string]metrics.Benchmark, len(pkg.GoBenchmarks))
+		for _, b := range pkg.GoBenchmarks {
+			m[b.Name] = b
+		}
+		prevBenches[pkg.Path] = m
+	}
+
+	var regressions []BenchmarkRegression
+	for _, pkg := range current {
+		prevForPkg, ok := prevBenches[pkg.Path]
+		if !ok {
+			continue
+		}
+		for _, b := range pkg.GoBenchmarks {
+			prev, ok := prevForPkg[b.Name]
+			if !ok || prev.NsPerOp <= 0 {
+				continue
+			}
+			deltaPct := (b.NsPerOp - prev.NsPerOp) / prev.NsPerOp * 100
+			if deltaPct >= thresholdPct {
+				regressions = append(regressions, BenchmarkRegression{
+					Package:         pkg.Path,
+					Benchmark:       b.Name,
+					PreviousNsPerOp: prev.NsPerOp,
+					CurrentNsPerOp:  b.NsPerOp,
+					DeltaPct:        deltaPct,
+				})
+			}
+		}
+	}
+
+	sort.Slice(regressions, func(i, j int) bool {
+		if regressions[i].Package != regressions[j].Package {
+			return regressions[i].Package < regressions[j].Package
+		}
+		return regressions[i].Benchmark < regressions[j].Benchmark
+	})
+	return regressions
+}
@@ -11,16 +11,25 @@ import (
 
 // Summary contains high-level metrics
 type Summary struct {
-	BazelizationPct   float64 `json:"bazelizationPct"`
-	TestCoveragePct   float64 `json:"testCoveragePct"`
-	BazelizedTestsPct float64 `json:"bazelizedTestsPct"`
-	TotalPackages     int     `json:"totalPackages"`
-	TotalBuildFiles   int     `json:"totalBuildFiles"`
-	TotalTestFiles    int     `json:"totalTestFiles"`
-	TotalGoFiles      int     `json:"totalGoFiles"`
-	PackagesWithBuild int     `json:"packagesWithBuild"`
-	PackagesWithTests int     `json:"packagesWithTests"`
-	TotalGoTestTargets int    `json:"totalGoTestTargets"`
+	BazelizationPct    float64 `json:"bazelizationPct"`
+	TestCoveragePct    float64 `json:"testCoveragePct"`
+	BazelizedTestsPct  float64 `json:"bazelizedTestsPct"`
+	TotalPackages      int     `json:"totalPackages"`
+	TotalBuildFiles    int     `json:"totalBuildFiles"`
+	TotalTestFiles     int     `json:"totalTestFiles"`
+	TotalGoFiles       int     `json:"totalGoFiles"`
+	PackagesWithBuild  int     `json:"packagesWithBuild"`
+	PackagesWithTests  int     `json:"packagesWithTests"`
+	TotalGoTestTargets int     `json:"totalGoTestTargets"`
+
+	// TestsBySize, PackagesWithRaceEnabled, and TagsDistribution only get
+	// populated when the scanner captures per-target attributes (the
+	// Starlark-aware parser and QueryScanner; the regex scanner only
+	// produces target counts). They show not just "is it bazelized" but
+	// "how well is it configured".
+	TestsBySize             map[string]int `json:"testsBySize,omitempty"`
+	PackagesWithRaceEnabled int            `json:"packagesWithRaceEnabled"`
+	TagsDistribution        map[string]int `json:"tagsDistribution,omitempty"`
 }
 
 // DirectoryMetrics contains metrics grouped by top-level directory
@@ -56,14 +65,91 @@ type Report struct {
 // SpeedReport contains benchmark comparison data
 type SpeedReport struct {
 	Packages []PackageBenchmark `json:"packages"`
+	Summary  SpeedSummary       `json:"summary"`
+}
+
+// SpeedSummary gives a headline view across every benchmarked package.
+type SpeedSummary struct {
+	PackagesCompared int     `json:"packagesCompared"`
+	GeomeanSpeedup   float64 `json:"geomeanSpeedup"` // goTest/bazelWarm across packages; >1 means bazel is faster
 }
 
-// PackageBenchmark contains timing for a single package
+// PackageBenchmark contains repeated-run timing for a single package,
+// comparing go test against bazel test cold and warm.
 type PackageBenchmark struct {
-	Path             string `json:"path"`
-	GoTestMs         int64  `json:"goTestMs"`
-	BazelTestColdMs  int64  `json:"bazelTestColdMs"`
-	BazelTestWarmMs  int64  `json:"bazelTestWarmMs"`
+	Path string `json:"path"`
+
+	GoTestSamples []int64 `json:"goTestSamplesMs"`
+	GoTestStats   Stats   `json:"goTestStats"`
+
+	BazelColdSamples []int64 `json:"bazelTestColdSamplesMs"`
+	BazelColdStats   Stats   `json:"bazelTestColdStats"`
+
+	BazelWarmSamples []int64 `json:"bazelTestWarmSamplesMs"`
+	BazelWarmStats   Stats   `json:"bazelTestWarmStats"`
+
+	// DeltaPct is (bazelWarm - goTest) / goTest * 100; negative means bazel is faster.
+	DeltaPct    float64 `json:"deltaPct"`
+	PValue      float64 `json:"pValue"`
+	Significant bool    `json:"significant"`
+
+	GoBenchmarks    []Benchmark      `json:"goBenchmarks,omitempty"`
+	BazelBenchmarks []Benchmark      `json:"bazelBenchmarks,omitempty"`
+	BenchmarkDeltas []BenchmarkDelta `json:"benchmarkDeltas,omitempty"`
+
+	BazelColdBreakdown *BazelTestBreakdown `json:"bazelColdBreakdown,omitempty"`
+	BazelWarmBreakdown *BazelTestBreakdown `json:"bazelWarmBreakdown,omitempty"`
+}
+
+// BazelTestBreakdown gives a phase-accurate view of a single `bazel test`
+// invocation, parsed from its Build Event Protocol (BEP) JSON stream rather
+// than a single wall-clock measurement that conflates startup, analysis,
+// action execution, and test runtime.
+type BazelTestBreakdown struct {
+	TotalMs        int64 `json:"totalMs"`
+	AnalysisMs     int64 `json:"analysisMs"`
+	ExecutionMs    int64 `json:"executionMs"`
+	CriticalPathMs int64 `json:"criticalPathMs"`
+
+	RemoteActions int     `json:"remoteActions"`
+	LocalActions  int     `json:"localActions"`
+	CachedActions int     `json:"cachedActions"`
+	CacheHitRatio float64 `json:"cacheHitRatio"`
+
+	TestAttempts []TestAttempt `json:"testAttempts,omitempty"`
+}
+
+// TestAttempt is the timing of a single test attempt reported in the BEP
+// stream's testResult events.
+type TestAttempt struct {
+	Attempt    int    `json:"attempt"`
+	DurationMs int64  `json:"durationMs"`
+	Status     string `json:"status"`
+	CacheHit   bool   `json:"cacheHit"`
+}
+
+// Benchmark is a single parsed line from `go test -bench` output.
+type Benchmark struct {
+	Name              string  `json:"name"`
+	Procs             int     `json:"procs"`
+	N                 int     `json:"n"`
+	NsPerOp           float64 `json:"nsPerOp"`
+	AllocedBytesPerOp uint64  `json:"allocedBytesPerOp,omitempty"`
+	AllocsPerOp       uint64  `json:"allocsPerOp,omitempty"`
+	MBPerS            float64 `json:"mbPerS,omitempty"`
+}
+
+// BenchmarkDelta compares a single microbenchmark between go test and bazel
+// test, so regressions can be spotted at the sub-test level rather than just
+// at the whole-test-binary level.
+type BenchmarkDelta struct {
+	Name                      string  `json:"name"`
+	GoNsPerOp                 float64 `json:"goNsPerOp"`
+	BazelNsPerOp              float64 `json:"bazelNsPerOp"`
+	DeltaNsPerOpPct           float64 `json:"deltaNsPerOpPct"`
+	GoAllocedBytesPerOp       uint64  `json:"goAllocedBytesPerOp"`
+	BazelAllocedBytesPerOp    uint64  `json:"bazelAllocedBytesPerOp"`
+	DeltaAllocedBytesPerOpPct float64 `json:"deltaAllocedBytesPerOpPct"`
 }
 
 // Calculator computes metrics from scan results
@@ -137,9 +223,55 @@ func (c *Calculator) Calculate() *Report {
 	// Calculate directory breakdown
 	report.DirectoryBreakdown = c.calculateDirectoryBreakdown()
 
+	// Calculate per-target configuration metrics (only meaningful for
+	// scanners that populate Package.Targets, i.e. the Starlark parser and
+	// QueryScanner).
+	c.calculateTargetMetrics(&report.Summary)
+
 	return report
 }
 
+// calculateTargetMetrics fills in the Summary fields derived from per-target
+// attributes: how go_test targets are distributed across Bazel's "size"
+// buckets, how many packages enable -race, and how tags are used across the
+// repo. Packages scanned without per-target attributes (the regex scanner)
+// simply contribute nothing here.
+func (c *Calculator) calculateTargetMetrics(summary *Summary) {
+	testsBySize := make(map[string]int)
+	tagsDistribution := make(map[string]int)
+	racePackages := 0
+
+	for _, pkg := range c.scanResult.Packages {
+		hasRace := false
+		for _, t := range pkg.Targets {
+			if t.Kind == "go_test" {
+				size := t.Size
+				if size == "" {
+					size = "medium" // Bazel's own default test size
+				}
+				testsBySize[size]++
+			}
+			if t.Race {
+				hasRace = true
+			}
+			for _, tag := range t.Tags {
+				tagsDistribution[tag]++
+			}
+		}
+		if hasRace {
+			racePackages++
+		}
+	}
+
+	if len(testsBySize) > 0 {
+		summary.TestsBySize = testsBySize
+	}
+	if len(tagsDistribution) > 0 {
+		summary.TagsDistribution = tagsDistribution
+	}
+	summary.PackagesWithRaceEnabled = racePackages
+}
+
 func (c *Calculator) calculateDirectoryBreakdown() []*DirectoryMetrics {
 	dirMap := make(map[string]*DirectoryMetrics)
 
@@ -193,6 +325,41 @@ func getTopLevelDir(path string) string {
 	return ""
 }
 
+// CompareBenchmarks matches go and bazel microbenchmarks by name and
+// computes per-benchmark deltas, so regressions can be spotted below the
+// level of "the whole test binary got slower".
+func CompareBenchmarks(goBenches, bazelBenches []Benchmark) []BenchmarkDelta {
+	bazelByName := make(map[string]Benchmark, len(bazelBenches))
+	for _, b := range bazelBenches {
+		bazelByName[b.Name] = b
+	}
+
+	var deltas []BenchmarkDelta
+	for _, g := range goBenches {
+		b, ok := bazelByName[g.Name]
+		if !ok {
+			continue
+		}
+
+		delta := BenchmarkDelta{
+			Name:                   g.Name,
+			GoNsPerOp:              g.NsPerOp,
+			BazelNsPerOp:           b.NsPerOp,
+			GoAllocedBytesPerOp:    g.AllocedBytesPerOp,
+			BazelAllocedBytesPerOp: b.AllocedBytesPerOp,
+		}
+		if g.NsPerOp > 0 {
+			delta.DeltaNsPerOpPct = (b.NsPerOp - g.NsPerOp) / g.NsPerOp * 100
+		}
+		if g.AllocedBytesPerOp > 0 {
+			delta.DeltaAllocedBytesPerOpPct = float64(int64(b.AllocedBytesPerOp)-int64(g.AllocedBytesPerOp)) / float64(g.AllocedBytesPerOp) * 100
+		}
+		deltas = append(deltas, delta)
+	}
+
+	return deltas
+}
+
 // SetSpeedComparison adds speed comparison data to the report
 func (r *Report) SetSpeedComparison(speed *SpeedReport) {
 	r.SpeedComparison = speed
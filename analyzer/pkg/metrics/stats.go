@@ -0,0 +1,229 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+)
+
+// Stats holds summary statistics for a set of repeated timing samples,
+// e.g. the wall-clock durations of N runs of the same benchmark.
+type Stats struct {
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	Min    int64   `json:"min"`
+	StdDev float64 `json:"stdDev"`
+	CV     float64 `json:"cv"` // coefficient of variation: stddev / mean
+}
+
+// ComputeStats returns summary statistics for samples. The first sample is
+// treated as an unavoidable warm-up measurement by callers and should
+// already be excluded before this is called.
+func ComputeStats(samples []int64) Stats {
+	if len(samples) == 0 {
+		return Stats{}
+	}
+
+	sorted := make([]int64, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum float64
+	for _, s := range samples {
+		sum += float64(s)
+	}
+	mean := sum / float64(len(samples))
+
+	var sumSq float64
+	for _, s := range samples {
+		d := float64(s) - mean
+		sumSq += d * d
+	}
+	var stdDev float64
+	if len(samples) > 1 {
+		stdDev = math.Sqrt(sumSq / float64(len(samples)-1))
+	}
+
+	var cv float64
+	if mean != 0 {
+		cv = stdDev / mean
+	}
+
+	return Stats{
+		Mean:   mean,
+		Median: median(sorted),
+		Min:    sorted[0],
+		StdDev: stdDev,
+		CV:     cv,
+	}
+}
+
+func median(sorted []int64) float64 {
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return float64(sorted[n/2])
+	}
+	return float64(sorted[n/2-1]+sorted[n/2]) / 2
+}
+
+// WelchTTest runs Welch's t-test (unequal variances) comparing two sets of
+// samples and returns an approximate two-tailed p-value. It falls back to a
+// p-value of 1 (no evidence of a difference) when there isn't enough data to
+// say anything meaningful.
+func WelchTTest(a, b []int64) float64 {
+	if len(a) < 2 || len(b) < 2 {
+		return 1
+	}
+
+	statsA := ComputeStats(a)
+	statsB := ComputeStats(b)
+
+	varA := statsA.StdDev * statsA.StdDev
+	varB := statsB.StdDev * statsB.StdDev
+	nA := float64(len(a))
+	nB := float64(len(b))
+
+	se := math.Sqrt(varA/nA + varB/nB)
+	if se == 0 {
+		return 1
+	}
+
+	t := (statsA.Mean - statsB.Mean) / se
+
+	// Welch-Satterthwaite degrees of freedom.
+	num := (varA/nA + varB/nB) * (varA/nA + varB/nB)
+	den := (varA*varA)/(nA*nA*(nA-1)) + (varB*varB)/(nB*nB*(nB-1))
+	df := nA + nB - 2
+	if den != 0 {
+		df = num / den
+	}
+
+	return twoTailedPValue(t, df)
+}
+
+// twoTailedPValue approximates the two-tailed p-value for a t-statistic
+// with the given degrees of freedom via the Student's t CDF.
+func twoTailedPValue(t, df float64) float64 {
+	if df <= 0 {
+		return 1
+	}
+	p := 2 * (1 - studentTCDF(math.Abs(t), df))
+	if p < 0 {
+		p = 0
+	}
+	if p > 1 {
+		p = 1
+	}
+	return p
+}
+
+// studentTCDF approximates the CDF of the Student's t-distribution using
+// the regularized incomplete beta function, good enough for flagging
+// significance rather than publication-grade statistics.
+func studentTCDF(t, df float64) float64 {
+	x := df / (df + t*t)
+	ib := incompleteBeta(x, df/2, 0.5)
+	if t > 0 {
+		return 1 - 0.5*ib
+	}
+	return 0.5 * ib
+}
+
+// incompleteBeta computes the regularized incomplete beta function I_x(a, b)
+// via a continued fraction expansion (Lentz's algorithm).
+func incompleteBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+
+	lbeta := lgamma(a+b) - lgamma(a) - lgamma(b)
+	front := math.Exp(lbeta+a*math.Log(x)+b*math.Log(1-x)) / a
+
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(x, a, b)
+	}
+	return 1 - front*betaContinuedFraction(1-x, b, a)*a/b
+}
+
+func lgamma(x float64) float64 {
+	v, _ := math.Lgamma(x)
+	return v
+}
+
+func betaContinuedFraction(x, a, b float64) float64 {
+	const maxIter = 200
+	const epsilon = 1e-10
+
+	qab := a + b
+	qap := a + 1
+	qam := a - 1
+	c := 1.0
+	d := 1 - qab*x/qap
+	if math.Abs(d) < 1e-30 {
+		d = 1e-30
+	}
+	d = 1 / d
+	h := d
+
+	for m := 1; m <= maxIter; m++ {
+		fm := float64(m)
+		m2 := 2 * fm
+
+		aa := fm * (b - fm) * x / ((qam + m2) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < 1e-30 {
+			d = 1e-30
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < 1e-30 {
+			c = 1e-30
+		}
+		d = 1 / d
+		h *= d * c
+
+		aa = -(a + fm) * (qab + fm) * x / ((a + m2) * (qap + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < 1e-30 {
+			d = 1e-30
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < 1e-30 {
+			c = 1e-30
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+
+		if math.Abs(del-1) < epsilon {
+			break
+		}
+	}
+
+	return h
+}
+
+// Geomean returns the geometric mean of a set of positive ratios, used to
+// summarize per-package speedups into a single headline number.
+func Geomean(ratios []float64) float64 {
+	if len(ratios) == 0 {
+		return 0
+	}
+	var sumLog float64
+	n := 0
+	for _, r := range ratios {
+		if r <= 0 {
+			continue
+		}
+		sumLog += math.Log(r)
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return math.Exp(sumLog / float64(n))
+}
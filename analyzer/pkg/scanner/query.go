@@ -0,0 +1,292 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const queryKind = `kind("go_(library|test|binary|proto_library)", //...)`
+
+// QueryScanner discovers Bazel targets via `bazel query` instead of
+// regex-grepping BUILD files, so it also sees macros, generated targets,
+// load()-renamed rules, and targets defined in .bzl files that the regex
+// scanner would miss. It falls back to the regex scanner when bazel isn't
+// available or the workspace can't be queried.
+type QueryScanner struct {
+	repoPath string
+	fallback *Scanner
+}
+
+// NewQueryScanner creates a scanner that queries Bazel directly.
+func NewQueryScanner(repoPath string) *QueryScanner {
+	return &QueryScanner{
+		repoPath: repoPath,
+		fallback: NewScanner(repoPath),
+	}
+}
+
+// Scan implements ScanRunner.
+func (q *QueryScanner) Scan() (*ScanResult, error) {
+	wsPath, wsModTime, ok := findWorkspaceFile(q.repoPath)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Warning: no MODULE.bazel/WORKSPACE found, falling back to regex scanner\n")
+		return q.fallback.Scan()
+	}
+
+	if _, err := exec.LookPath("bazel"); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: bazel not found on PATH, falling back to regex scanner\n")
+		return q.fallback.Scan()
+	}
+
+	if cached, ok := q.loadCache(wsModTime); ok {
+		return cached, nil
+	}
+
+	targets, err := q.runQuery()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: bazel query failed (%v), falling back to regex scanner\n", err)
+		return q.fallback.Scan()
+	}
+
+	packageMap, result, err := collectFileStats(q.repoPath, q.fallback.skipDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	applyTargets(q.repoPath, packageMap, result, targets)
+
+	// Sort by RelPath so output is deterministic regardless of Go's map
+	// iteration order, matching the regex Scanner.
+	for _, pkg := range packageMap {
+		if pkg.GoFileCount > 0 || pkg.TestFileCount > 0 || len(pkg.Targets) > 0 {
+			result.Packages = append(result.Packages, pkg)
+		}
+	}
+	sort.Slice(result.Packages, func(i, j int) bool {
+		return result.Packages[i].RelPath < result.Packages[j].RelPath
+	})
+
+	q.saveCache(wsModTime, result)
+
+	_ = wsPath // kept for clarity/debuggability; not otherwise needed
+	return result, nil
+}
+
+// runQuery shells out to `bazel query` and parses the streamed jsonproto
+// output, one JSON object per line.
+func (q *QueryScanner) runQuery() ([]queryTarget, error) {
+	cmd := exec.Command("bazel", "query", "--output=streamed_jsonproto", queryKind)
+	cmd.Dir = q.repoPath
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("bazel query: %w", err)
+	}
+
+	var targets []queryTarget
+	scanner := bufio.NewScanner(&stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var t queryTarget
+		if err := json.Unmarshal(line, &t); err != nil {
+			continue // tolerate stray non-JSON lines
+		}
+		if t.Type == "RULE" && t.Rule != nil {
+			targets = append(targets, t)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return targets, nil
+}
+
+// applyTargets overlays the Bazel targets discovered via `bazel query` onto
+// the per-directory package map built from the filesystem walk. packageMap
+// is keyed by absolute directory path (the same key collectFileStats uses),
+// so target labels are converted from repo-relative to absolute paths before
+// lookup; otherwise every target would create a second, disjoint package
+// entry instead of merging onto the one holding its file stats.
+func applyTargets(repoPath string, packageMap map[string]*Package, result *ScanResult, targets []queryTarget) {
+	for _, qt := range targets {
+		rule := qt.Rule
+		relPath, name := labelToPath(rule.Name)
+		dir := repoPath
+		if relPath != "." {
+			dir = filepath.Join(repoPath, relPath)
+		}
+
+		pkg, exists := packageMap[dir]
+		if !exists {
+			pkg = &Package{Path: dir, RelPath: relPath}
+			packageMap[dir] = pkg
+		}
+		pkg.HasBuildFile = true
+
+		target := Target{
+			Label:      rule.Name,
+			Kind:       rule.RuleClass,
+			Srcs:       rule.stringListAttr("srcs"),
+			Deps:       rule.stringListAttr("deps"),
+			Size:       rule.stringAttr("size"),
+			Timeout:    rule.stringAttr("timeout"),
+			Tags:       rule.stringListAttr("tags"),
+			Visibility: rule.stringListAttr("visibility"),
+			Race:       raceOrPureEnabled(rule.stringAttr("race")),
+			Pure:       raceOrPureEnabled(rule.stringAttr("pure")),
+			ShardCount: rule.intAttr("shard_count"),
+		}
+		pkg.Targets = append(pkg.Targets, target)
+
+		switch rule.RuleClass {
+		case "go_test":
+			pkg.GoTestTargets++
+		case "go_library":
+			pkg.GoLibraryTargets++
+		case "go_binary":
+			pkg.GoBinaryTargets++
+		}
+
+		_ = name
+	}
+}
+
+// labelToPath converts a Bazel label like "//foo/bar:baz_test" into its
+// package's repo-relative path ("foo/bar") and target name ("baz_test").
+func labelToPath(label string) (relPath, name string) {
+	label = strings.TrimPrefix(label, "//")
+	pkgPath := label
+	if idx := strings.LastIndex(label, ":"); idx != -1 {
+		pkgPath = label[:idx]
+		name = label[idx+1:]
+	}
+	if pkgPath == "" {
+		return ".", name
+	}
+	return pkgPath, name
+}
+
+// findWorkspaceFile looks for MODULE.bazel, WORKSPACE, or WORKSPACE.bazel at
+// the repository root and returns its path and modification time.
+func findWorkspaceFile(repoPath string) (path string, modTime time.Time, ok bool) {
+	for _, name := range []string{"MODULE.bazel", "WORKSPACE", "WORKSPACE.bazel"} {
+		p := filepath.Join(repoPath, name)
+		if info, err := os.Stat(p); err == nil {
+			return p, info.ModTime(), true
+		}
+	}
+	return "", time.Time{}, false
+}
+
+type queryCacheEntry struct {
+	WorkspaceModTime time.Time   `json:"workspaceModTime"`
+	Result           *ScanResult `json:"result"`
+}
+
+func (q *QueryScanner) cachePath() string {
+	return filepath.Join(q.repoPath, ".bazel-metrics", "query-cache.json")
+}
+
+func (q *QueryScanner) loadCache(wsModTime time.Time) (*ScanResult, bool) {
+	data, err := os.ReadFile(q.cachePath())
+	if err != nil {
+		return nil, false
+	}
+
+	var entry queryCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+
+	if !entry.WorkspaceModTime.Equal(wsModTime) {
+		return nil, false
+	}
+
+	return entry.Result, true
+}
+
+func (q *QueryScanner) saveCache(wsModTime time.Time, result *ScanResult) {
+	entry := queryCacheEntry{WorkspaceModTime: wsModTime, Result: result}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	cachePath := q.cachePath()
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return
+	}
+	_ = os.WriteFile(cachePath, data, 0644)
+}
+
+// queryTarget mirrors the subset of the streamed_jsonproto Target message
+// we care about.
+type queryTarget struct {
+	Type string     `json:"type"`
+	Rule *queryRule `json:"rule"`
+}
+
+type queryRule struct {
+	Name      string           `json:"name"`
+	RuleClass string           `json:"ruleClass"`
+	Attribute []queryAttribute `json:"attribute"`
+}
+
+type queryAttribute struct {
+	Name            string   `json:"name"`
+	Type            string   `json:"type"`
+	StringValue     string   `json:"stringValue"`
+	StringListValue []string `json:"stringListValue"`
+	BooleanValue    bool     `json:"booleanValue"`
+	IntValue        int      `json:"intValue"`
+}
+
+func (r *queryRule) attr(name string) (queryAttribute, bool) {
+	for _, a := range r.Attribute {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return queryAttribute{}, false
+}
+
+func (r *queryRule) stringAttr(name string) string {
+	a, ok := r.attr(name)
+	if !ok {
+		return ""
+	}
+	return a.StringValue
+}
+
+func (r *queryRule) stringListAttr(name string) []string {
+	a, ok := r.attr(name)
+	if !ok {
+		return nil
+	}
+	return a.StringListValue
+}
+
+func (r *queryRule) intAttr(name string) int {
+	a, ok := r.attr(name)
+	if !ok {
+		return 0
+	}
+	return a.IntValue
+}
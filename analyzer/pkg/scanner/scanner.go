@@ -2,10 +2,17 @@ package scanner
 
 import (
 	"bufio"
+	"context"
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Package represents a Go package directory with its metadata
@@ -19,6 +26,24 @@ type Package struct {
 	GoTestTargets    int      `json:"goTestTargetCount"`
 	GoLibraryTargets int      `json:"goLibraryTargetCount"`
 	GoBinaryTargets  int      `json:"goBinaryTargetCount"`
+	Targets          []Target `json:"targets,omitempty"`
+}
+
+// Target is a single Bazel rule instance discovered for a package, e.g. a
+// go_test or go_library. The QueryScanner and the Starlark-aware scanner
+// both populate this; the plain regex scanner only produces target counts.
+type Target struct {
+	Label      string   `json:"label"`
+	Kind       string   `json:"kind"` // e.g. go_test, go_library, go_binary, go_proto_library
+	Srcs       []string `json:"srcs,omitempty"`
+	Deps       []string `json:"deps,omitempty"`
+	Size       string   `json:"size,omitempty"`
+	Timeout    string   `json:"timeout,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Visibility []string `json:"visibility,omitempty"`
+	Race       bool     `json:"race,omitempty"`
+	Pure       bool     `json:"pure,omitempty"`
+	ShardCount int      `json:"shardCount,omitempty"`
 }
 
 // ScanResult contains the complete scan results
@@ -30,6 +55,12 @@ type ScanResult struct {
 	TotalTests   int        `json:"totalTestFiles"`
 }
 
+// ScanRunner is implemented by every scanner backend (regex-based, bazel
+// query-based, ...) so the CLI can select one at runtime.
+type ScanRunner interface {
+	Scan() (*ScanResult, error)
+}
+
 // Scanner scans a repository for Bazel and Go metrics
 type Scanner struct {
 	repoPath    string
@@ -37,6 +68,8 @@ type Scanner struct {
 	goTestRegex *regexp.Regexp
 	goLibRegex  *regexp.Regexp
 	goBinRegex  *regexp.Regexp
+	concurrency int
+	timeout     time.Duration
 }
 
 // NewScanner creates a new scanner for the given repository path
@@ -44,36 +77,211 @@ func NewScanner(repoPath string) *Scanner {
 	return &Scanner{
 		repoPath: repoPath,
 		skipDirs: map[string]bool{
-			".git":         true,
-			"bazel-bin":    true,
-			"bazel-out":    true,
+			".git":           true,
+			"bazel-bin":      true,
+			"bazel-out":      true,
 			"bazel-testlogs": true,
-			"node_modules": true,
-			".cache":       true,
-			"vendor":       true,
+			"node_modules":   true,
+			".cache":         true,
+			"vendor":         true,
 		},
 		goTestRegex: regexp.MustCompile(`(?m)^\s*go_test\s*\(`),
 		goLibRegex:  regexp.MustCompile(`(?m)^\s*go_library\s*\(`),
 		goBinRegex:  regexp.MustCompile(`(?m)^\s*go_binary\s*\(`),
+		concurrency: runtime.NumCPU(),
+	}
+}
+
+// WithConcurrency sets the number of workers used to parse BUILD files
+// concurrently. n <= 0 is ignored and leaves the default (runtime.NumCPU()).
+func (s *Scanner) WithConcurrency(n int) *Scanner {
+	if n > 0 {
+		s.concurrency = n
 	}
+	return s
+}
+
+// WithTimeout bounds how long Scan will run before aborting the walk via
+// context cancellation. d <= 0 means no timeout.
+func (s *Scanner) WithTimeout(d time.Duration) *Scanner {
+	s.timeout = d
+	return s
+}
+
+// errScanTimeout is returned by Scan when the configured timeout elapses
+// before the walk completes.
+var errScanTimeout = errors.New("scan timed out")
+
+// buildJob is a BUILD file discovered during the walk, queued for a worker
+// to parse.
+type buildJob struct {
+	path string
+	pkg  *Package
 }
 
-// Scan performs a full scan of the repository
+// buildJobResult is a parsed BUILD file, routed back to the single writer
+// goroutine that owns packageMap.
+type buildJobResult struct {
+	pkg         *Package
+	targets     *buildTargets
+	fileTargets []Target
+}
+
+// Scan performs a full scan of the repository. It walks the tree with
+// filepath.WalkDir (which avoids an extra Lstat per entry that
+// filepath.Walk performs) on a single goroutine, but hands BUILD file
+// parsing off to a bounded worker pool so I/O-bound regex parsing doesn't
+// serialize behind the walk on large monorepos.
 func (s *Scanner) Scan() (*ScanResult, error) {
+	ctx := context.Background()
+	var cancel context.CancelFunc
+	if s.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
 	result := &ScanResult{
 		RepoPath: s.repoPath,
 		Packages: make([]*Package, 0),
 	}
-
 	packageMap := make(map[string]*Package)
 
-	err := filepath.Walk(s.repoPath, func(path string, info os.FileInfo, err error) error {
+	filesCh := make(chan fileEvent, 256)
+	jobsCh := make(chan buildJob, 256)
+	resultsCh := make(chan buildJobResult, 256)
+
+	workers := s.concurrency
+	if workers <= 0 {
+		workers = 1
+	}
+	var workerWg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		workerWg.Add(1)
+		go func() {
+			defer workerWg.Done()
+			for job := range jobsCh {
+				// Prefer the Starlark-aware parser; fall back to the
+				// regex scanner for BUILD files it can't parse (syntax
+				// errors, exotic macros, etc.).
+				targets, fileTargets, err := s.parseBuildFileStarlark(job.path)
+				if err != nil {
+					fileTargets = nil
+					targets, err = s.parseBuildFile(job.path)
+				}
+				if err != nil {
+					targets = &buildTargets{}
+				}
+				select {
+				case resultsCh <- buildJobResult{pkg: job.pkg, targets: targets, fileTargets: fileTargets}:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+	go func() {
+		workerWg.Wait()
+		close(resultsCh)
+	}()
+
+	var walkErr error
+	go func() {
+		walkErr = s.walk(ctx, filesCh)
+		close(filesCh)
+	}()
+
+	// Single writer goroutine: merges file events from the walk and parsed
+	// BUILD results from the worker pool into packageMap. Running as the
+	// body of Scan (not a separate goroutine) keeps the return path simple.
+	//
+	// Queued BUILD jobs are sent to jobsCh via a select case that's only
+	// enabled while a job is pending, rather than a blocking send, so this
+	// goroutine never stalls waiting for workers while workers are in turn
+	// waiting for it to drain resultsCh (which would deadlock once either
+	// buffered channel fills up on a large tree).
+	open := true
+	resultsOpen := true
+	jobsClosed := false
+	var pending []buildJob
+	for open || resultsOpen || len(pending) > 0 {
+		var sendCh chan buildJob
+		var nextJob buildJob
+		if len(pending) > 0 {
+			sendCh = jobsCh
+			nextJob = pending[0]
+		}
+
+		select {
+		case msg, ok := <-filesCh:
+			if !ok {
+				open = false
+				filesCh = nil
+				continue
+			}
+			if job, isBuild := s.applyFileEvent(packageMap, result, msg); isBuild {
+				pending = append(pending, job)
+			}
+		case res, ok := <-resultsCh:
+			if !ok {
+				resultsOpen = false
+				resultsCh = nil
+				continue
+			}
+			res.pkg.GoTestTargets = res.targets.goTests
+			res.pkg.GoLibraryTargets = res.targets.goLibs
+			res.pkg.GoBinaryTargets = res.targets.goBins
+			res.pkg.Targets = res.fileTargets
+		case sendCh <- nextJob:
+			pending = pending[1:]
+		}
+
+		if !open && len(pending) == 0 && !jobsClosed {
+			close(jobsCh)
+			jobsClosed = true
+		}
+	}
+
+	if walkErr != nil {
+		return nil, walkErr
+	}
+	if ctx.Err() != nil {
+		return nil, errScanTimeout
+	}
+
+	// Convert map to slice, only include Go packages. Sort by RelPath so
+	// output is deterministic regardless of walk/worker-pool scheduling.
+	for _, pkg := range packageMap {
+		if pkg.GoFileCount > 0 || pkg.TestFileCount > 0 {
+			result.Packages = append(result.Packages, pkg)
+		}
+	}
+	sort.Slice(result.Packages, func(i, j int) bool {
+		return result.Packages[i].RelPath < result.Packages[j].RelPath
+	})
+
+	return result, nil
+}
+
+// fileEvent is a single relevant file discovered during the walk.
+type fileEvent struct {
+	dir      string
+	relDir   string
+	path     string
+	filename string
+}
+
+// walk enumerates s.repoPath with filepath.WalkDir, sending every BUILD,
+// Go, and Go test file it finds to eventsCh. It stops early if ctx is
+// cancelled.
+func (s *Scanner) walk(ctx context.Context, eventsCh chan<- fileEvent) error {
+	return filepath.WalkDir(s.repoPath, func(path string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		if err != nil {
 			return nil // Skip files we can't access
 		}
 
-		// Skip hidden and excluded directories
-		if info.IsDir() {
+		if d.IsDir() {
 			base := filepath.Base(path)
 			if strings.HasPrefix(base, ".") || s.skipDirs[base] || strings.HasPrefix(base, "bazel-") {
 				return filepath.SkipDir
@@ -81,13 +289,86 @@ func (s *Scanner) Scan() (*ScanResult, error) {
 			return nil
 		}
 
+		filename := d.Name()
+		if filename != "BUILD" && filename != "BUILD.bazel" && !strings.HasSuffix(filename, ".go") {
+			return nil
+		}
+
 		dir := filepath.Dir(path)
 		relDir, _ := filepath.Rel(s.repoPath, dir)
 		if relDir == "" {
 			relDir = "."
 		}
 
-		// Get or create package entry
+		select {
+		case eventsCh <- fileEvent{dir: dir, relDir: relDir, path: path, filename: filename}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return nil
+	})
+}
+
+// applyFileEvent updates packageMap/result for a single file event and, for
+// BUILD files, queues a parse job for the worker pool. Only called from the
+// single writer goroutine in Scan.
+func (s *Scanner) applyFileEvent(packageMap map[string]*Package, result *ScanResult, msg fileEvent) (job buildJob, isBuildFile bool) {
+	pkg, exists := packageMap[msg.dir]
+	if !exists {
+		pkg = &Package{
+			Path:    msg.dir,
+			RelPath: msg.relDir,
+		}
+		packageMap[msg.dir] = pkg
+	}
+
+	switch {
+	case msg.filename == "BUILD" || msg.filename == "BUILD.bazel":
+		pkg.HasBuildFile = true
+		result.TotalBUILDs++
+		return buildJob{path: msg.path, pkg: pkg}, true
+	case strings.HasSuffix(msg.filename, "_test.go"):
+		pkg.HasTestFiles = true
+		pkg.TestFileCount++
+		result.TotalTests++
+	case strings.HasSuffix(msg.filename, ".go"):
+		pkg.GoFileCount++
+		result.TotalGoFiles++
+	}
+	return buildJob{}, false
+}
+
+// collectFileStats walks repoPath counting Go source and test files per
+// package directory, independent of how BUILD files get parsed. It's shared
+// by the regex scanner and the QueryScanner, which only differ in how they
+// learn about Bazel targets.
+func collectFileStats(repoPath string, skipDirs map[string]bool) (map[string]*Package, *ScanResult, error) {
+	result := &ScanResult{
+		RepoPath: repoPath,
+		Packages: make([]*Package, 0),
+	}
+
+	packageMap := make(map[string]*Package)
+
+	err := filepath.WalkDir(repoPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip files we can't access
+		}
+
+		if d.IsDir() {
+			base := filepath.Base(path)
+			if strings.HasPrefix(base, ".") || skipDirs[base] || strings.HasPrefix(base, "bazel-") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		dir := filepath.Dir(path)
+		relDir, _ := filepath.Rel(repoPath, dir)
+		if relDir == "" {
+			relDir = "."
+		}
+
 		pkg, exists := packageMap[dir]
 		if !exists {
 			pkg = &Package{
@@ -99,27 +380,16 @@ func (s *Scanner) Scan() (*ScanResult, error) {
 
 		filename := filepath.Base(path)
 
-		// Check for BUILD files
 		if filename == "BUILD" || filename == "BUILD.bazel" {
 			pkg.HasBuildFile = true
 			result.TotalBUILDs++
-
-			// Parse BUILD file for targets
-			targets, err := s.parseBuildFile(path)
-			if err == nil {
-				pkg.GoTestTargets = targets.goTests
-				pkg.GoLibraryTargets = targets.goLibs
-				pkg.GoBinaryTargets = targets.goBins
-			}
 		}
 
-		// Check for Go files
 		if strings.HasSuffix(filename, ".go") && !strings.HasSuffix(filename, "_test.go") {
 			pkg.GoFileCount++
 			result.TotalGoFiles++
 		}
 
-		// Check for test files
 		if strings.HasSuffix(filename, "_test.go") {
 			pkg.HasTestFiles = true
 			pkg.TestFileCount++
@@ -128,19 +398,11 @@ func (s *Scanner) Scan() (*ScanResult, error) {
 
 		return nil
 	})
-
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// Convert map to slice, only include Go packages
-	for _, pkg := range packageMap {
-		if pkg.GoFileCount > 0 || pkg.TestFileCount > 0 {
-			result.Packages = append(result.Packages, pkg)
-		}
-	}
-
-	return result, nil
+	return packageMap, result, nil
 }
 
 type buildTargets struct {
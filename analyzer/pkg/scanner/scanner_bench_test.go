@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// buildSyntheticRepo writes a synthetic repo of n packages, each with a
+// BUILD.bazel, a go_library source file, and a go_test source file, so
+// BenchmarkScan exercises the same file mix a bazelized Go monorepo would.
+func buildSyntheticRepo(tb testing.TB, n int) string {
+	tb.Helper()
+	root := tb.TempDir()
+
+	for i := 0; i < n; i++ {
+		dir := filepath.Join(root, fmt.Sprintf("pkg%d", i))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			tb.Fatal(err)
+		}
+
+		build := fmt.Sprintf(`go_library(
+    name = "pkg%d",
+    srcs = ["lib.go"],
+    deps = [],
+)
+
+go_test(
+    name = "pkg%d_test",
+    srcs = ["lib_test.go"],
+    embed = [":pkg%d"],
+    size = "small",
+)
+`, i, i, i)
+		if err := os.WriteFile(filepath.Join(dir, "BUILD.bazel"), []byte(build), 0644); err != nil {
+			tb.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "lib.go"), []byte(fmt.Sprintf("package pkg%d\n", i)), 0644); err != nil {
+			tb.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "lib_test.go"), []byte(fmt.Sprintf("package pkg%d\n", i)), 0644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	return root
+}
+
+// BenchmarkScan measures repository-scan throughput on a synthetic 10k-package
+// tree, the scale parallel scanning (WithConcurrency) is meant to help with.
+func BenchmarkScan(b *testing.B) {
+	root := buildSyntheticRepo(b, 10000)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		s := NewScanner(root).WithConcurrency(8)
+		if _, err := s.Scan(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkScanSerial is the b.N loop run with concurrency 1, so `go test
+// -bench BenchmarkScan` run locally shows the speedup the worker pool buys.
+func BenchmarkScanSerial(b *testing.B) {
+	root := buildSyntheticRepo(b, 10000)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		s := NewScanner(root).WithConcurrency(1)
+		if _, err := s.Scan(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
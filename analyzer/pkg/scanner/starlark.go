@@ -0,0 +1,114 @@
+package scanner
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/bazelbuild/buildtools/build"
+)
+
+// goRuleKinds are the rule kinds the scanner cares about, after resolving
+// any load() aliases.
+var goRuleKinds = map[string]bool{
+	"go_test":          true,
+	"go_library":       true,
+	"go_binary":        true,
+	"go_proto_library": true,
+}
+
+// parseBuildFileStarlark parses path with a real Starlark parser rather
+// than regexes, so it correctly counts rule invocations that aren't at
+// column zero, rules reached through simple list-comprehension macros
+// (build.File.Rules walks the whole AST), and go_test etc. renamed via
+// load("//:defs.bzl", my_test = "go_test"). It returns both the legacy
+// target-count summary and the full per-target attribute list.
+func (s *Scanner) parseBuildFileStarlark(path string) (*buildTargets, []Target, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := build.ParseBuild(path, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	aliases := loadAliases(f)
+
+	counts := &buildTargets{}
+	var result []Target
+
+	for _, rule := range f.Rules("") {
+		kind := rule.Kind()
+		if original, ok := aliases[kind]; ok {
+			kind = original
+		}
+		if !goRuleKinds[kind] {
+			continue
+		}
+
+		target := Target{
+			Label:      rule.Name(),
+			Kind:       kind,
+			Srcs:       rule.AttrStrings("srcs"),
+			Deps:       rule.AttrStrings("deps"),
+			Size:       rule.AttrString("size"),
+			Timeout:    rule.AttrString("timeout"),
+			Tags:       rule.AttrStrings("tags"),
+			Visibility: rule.AttrStrings("visibility"),
+			Race:       raceOrPureEnabled(rule.AttrString("race")),
+			Pure:       raceOrPureEnabled(rule.AttrString("pure")),
+			ShardCount: literalInt(rule.AttrLiteral("shard_count")),
+		}
+		result = append(result, target)
+
+		switch kind {
+		case "go_test":
+			counts.goTests++
+		case "go_library":
+			counts.goLibs++
+		case "go_binary":
+			counts.goBins++
+		}
+	}
+
+	return counts, result, nil
+}
+
+// loadAliases maps a renamed symbol to its original name for every
+// load(...) statement in f, e.g. load("//:defs.bzl", my_test = "go_test")
+// produces aliases["my_test"] = "go_test".
+func loadAliases(f *build.File) map[string]string {
+	aliases := make(map[string]string)
+	for _, stmt := range f.Stmt {
+		load, ok := stmt.(*build.LoadStmt)
+		if !ok {
+			continue
+		}
+		for i, from := range load.From {
+			if i >= len(load.To) {
+				break
+			}
+			aliases[load.To[i].Name] = from.Name
+		}
+	}
+	return aliases
+}
+
+// raceOrPureEnabled interprets rules_go's tri-state "on"/"off"/"auto" string
+// attribute used for go_test/go_library/go_binary's race and pure settings.
+// Only "on" counts as explicitly enabled; "auto" defers to the toolchain
+// default and "off"/unset mean disabled.
+func raceOrPureEnabled(value string) bool {
+	return value == "on"
+}
+
+// literalInt parses the source text of a simple Starlark integer literal,
+// defaulting to 0 for anything else.
+func literalInt(literal string) int {
+	n, err := strconv.Atoi(literal)
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,144 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bazelbuild/buildtools/build"
+)
+
+func TestParseBuildFileStarlark(t *testing.T) {
+	const buildContent = `
+load("//:defs.bzl", my_test = "go_test")
+
+go_library(
+    name = "foo",
+    srcs = ["foo.go"],
+    deps = ["//bar:bar"],
+    visibility = ["//visibility:public"],
+)
+
+go_test(
+    name = "foo_test",
+    srcs = ["foo_test.go"],
+    size = "small",
+    race = "on",
+    pure = "off",
+    shard_count = 4,
+    tags = ["unit", "fast"],
+)
+
+my_test(
+    name = "foo_aliased_test",
+    srcs = ["foo_aliased_test.go"],
+)
+`
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "BUILD.bazel")
+	if err := os.WriteFile(path, []byte(buildContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Scanner{repoPath: dir}
+	counts, targets, err := s.parseBuildFileStarlark(path)
+	if err != nil {
+		t.Fatalf("parseBuildFileStarlark() error = %v", err)
+	}
+
+	if counts.goLibs != 1 || counts.goTests != 2 {
+		t.Errorf("counts = %+v, want goLibs=1 goTests=2", counts)
+	}
+	if len(targets) != 3 {
+		t.Fatalf("got %d targets, want 3: %+v", len(targets), targets)
+	}
+
+	byName := make(map[string]Target, len(targets))
+	for _, tg := range targets {
+		byName[tg.Label] = tg
+	}
+
+	lib, ok := byName["foo"]
+	if !ok || lib.Kind != "go_library" {
+		t.Fatalf("foo target missing or wrong kind: %+v", lib)
+	}
+	if len(lib.Deps) != 1 || lib.Deps[0] != "//bar:bar" {
+		t.Errorf("foo deps = %v, want [//bar:bar]", lib.Deps)
+	}
+
+	test, ok := byName["foo_test"]
+	if !ok || test.Kind != "go_test" {
+		t.Fatalf("foo_test target missing or wrong kind: %+v", test)
+	}
+	if test.Size != "small" {
+		t.Errorf("foo_test size = %q, want small", test.Size)
+	}
+	if !test.Race {
+		t.Errorf("foo_test race = false, want true")
+	}
+	if test.Pure {
+		t.Errorf("foo_test pure = true, want false (pure = \"off\")")
+	}
+	if test.ShardCount != 4 {
+		t.Errorf("foo_test shardCount = %d, want 4", test.ShardCount)
+	}
+	if len(test.Tags) != 2 || test.Tags[0] != "unit" || test.Tags[1] != "fast" {
+		t.Errorf("foo_test tags = %v, want [unit fast]", test.Tags)
+	}
+
+	aliased, ok := byName["foo_aliased_test"]
+	if !ok || aliased.Kind != "go_test" {
+		t.Fatalf("foo_aliased_test should resolve its load() alias back to go_test, got %+v", aliased)
+	}
+}
+
+func TestLoadAliases(t *testing.T) {
+	const buildContent = `
+load("//:defs.bzl", my_test = "go_test", my_lib = "go_library")
+load("@rules_go//go:def.bzl", "go_binary")
+`
+	f, err := build.ParseBuild("BUILD.bazel", []byte(buildContent))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aliases := loadAliases(f)
+	want := map[string]string{
+		"my_test": "go_test",
+		"my_lib":  "go_library",
+	}
+	for k, v := range want {
+		if aliases[k] != v {
+			t.Errorf("aliases[%q] = %q, want %q", k, aliases[k], v)
+		}
+	}
+	// go_binary is loaded under its own name (no "x = ..." rename), so it
+	// maps to itself — harmless for alias resolution, which only matters
+	// when the local name differs from the original.
+	if got := aliases["go_binary"]; got != "go_binary" {
+		t.Errorf("aliases[go_binary] = %q, want go_binary (identity mapping for a non-renamed load)", got)
+	}
+}
+
+func TestRaceOrPureEnabledAndLiteralInt(t *testing.T) {
+	if raceOrPureEnabled("on") != true {
+		t.Errorf(`raceOrPureEnabled("on") = false, want true`)
+	}
+	if raceOrPureEnabled("off") != false {
+		t.Errorf(`raceOrPureEnabled("off") = true, want false`)
+	}
+	if raceOrPureEnabled("auto") != false {
+		t.Errorf(`raceOrPureEnabled("auto") = true, want false`)
+	}
+	if raceOrPureEnabled("") != false {
+		t.Errorf(`raceOrPureEnabled("") = true, want false`)
+	}
+
+	if literalInt("4") != 4 {
+		t.Errorf("literalInt(4) = %d, want 4", literalInt("4"))
+	}
+	if literalInt("not a number") != 0 {
+		t.Errorf("literalInt(not a number) = %d, want 0", literalInt("not a number"))
+	}
+}